@@ -0,0 +1,191 @@
+// Package hooks turns a YAML config file into a set of webhook endpoints
+// that run a shell command per request, GitHub-style.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Hook describes one named webhook endpoint.
+type Hook struct {
+	Name    string        `yaml:"name"`
+	Path    string        `yaml:"path"`
+	Method  string        `yaml:"method"`
+	Command string        `yaml:"command"`
+	Timeout time.Duration `yaml:"timeout"`
+	Secret  string        `yaml:"secret"`
+}
+
+type config struct {
+	Hooks []Hook `yaml:"hooks"`
+}
+
+// reservedPaths are the app's own routes, already registered on the same
+// mux that Register adds hooks to.
+var reservedPaths = map[string]bool{
+	"/":        true,
+	"/health":  true,
+	"/about":   true,
+	"/metrics": true,
+}
+
+// result is what each hook handler returns to the caller as JSON.
+type result struct {
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+}
+
+// LoadConfig reads and validates the hooks file at path: paths must be
+// unique, must not collide with the app's own routes, and every hook must
+// have a non-empty command.
+func LoadConfig(path string) ([]Hook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("hooks: reading %s: %w", path, err)
+	}
+
+	var cfg config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("hooks: parsing %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool, len(cfg.Hooks))
+	for i, h := range cfg.Hooks {
+		if h.Path == "" {
+			return nil, fmt.Errorf("hooks: entry %d has no path", i)
+		}
+		if reservedPaths[h.Path] {
+			return nil, fmt.Errorf("hooks: path %q is reserved for the app's own routes", h.Path)
+		}
+		if seen[h.Path] {
+			return nil, fmt.Errorf("hooks: duplicate path %q", h.Path)
+		}
+		seen[h.Path] = true
+
+		if strings.TrimSpace(h.Command) == "" {
+			return nil, fmt.Errorf("hooks: hook %q has no command", h.Path)
+		}
+
+		if h.Method == "" {
+			cfg.Hooks[i].Method = http.MethodPost
+		} else {
+			cfg.Hooks[i].Method = strings.ToUpper(h.Method)
+		}
+
+		if h.Timeout <= 0 {
+			cfg.Hooks[i].Timeout = 30 * time.Second
+		}
+	}
+
+	return cfg.Hooks, nil
+}
+
+// Register adds an http.HandlerFunc for every hook to mux.
+func Register(mux *http.ServeMux, hooks []Hook) {
+	for _, h := range hooks {
+		mux.HandleFunc(h.Path, newHandler(h))
+	}
+}
+
+// newHandler builds the http.HandlerFunc that verifies the optional HMAC
+// signature, then runs a hook's command with the request body and headers.
+func newHandler(h Hook) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != h.Method {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "reading request body", http.StatusBadRequest)
+			return
+		}
+
+		if h.Secret != "" {
+			if err := verifySignature(h.Secret, body, r.Header.Get("X-Hub-Signature-256")); err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+		defer cancel()
+
+		res, err := run(ctx, h, r.Header, body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("running hook: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+// verifySignature checks header against the HMAC-SHA256 of body using
+// secret, GitHub's "sha256=<hex>" X-Hub-Signature-256 format.
+func verifySignature(secret string, body []byte, header string) error {
+	const prefix = "sha256="
+	if !strings.HasPrefix(header, prefix) {
+		return fmt.Errorf("missing or malformed X-Hub-Signature-256")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	want := mac.Sum(nil)
+
+	got, err := hex.DecodeString(strings.TrimPrefix(header, prefix))
+	if err != nil || !hmac.Equal(got, want) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// run executes the hook's command with the request body on stdin and every
+// request header exposed as a WEBLUG_HEADER_<NAME> environment variable.
+func run(ctx context.Context, h Hook, header http.Header, body []byte) (result, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+	cmd.Stdin = bytes.NewReader(body)
+
+	env := os.Environ()
+	for name, values := range header {
+		key := "WEBLUG_HEADER_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		env = append(env, key+"="+strings.Join(values, ","))
+	}
+	cmd.Env = env
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	exitCode := 0
+	if err := cmd.Run(); err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return result{}, err
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	return result{
+		ExitCode: exitCode,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+	}, nil
+}
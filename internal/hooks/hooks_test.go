@@ -0,0 +1,120 @@
+package hooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hooks.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("writing test config: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfigDefaultsMethodAndTimeout(t *testing.T) {
+	path := writeConfig(t, `
+hooks:
+  - path: /hooks/deploy
+    command: echo hi
+`)
+
+	got, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: unexpected error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d hooks, want 1", len(got))
+	}
+	if got[0].Method != "POST" {
+		t.Errorf("Method = %q, want POST", got[0].Method)
+	}
+	if got[0].Timeout != 30_000_000_000 {
+		t.Errorf("Timeout = %v, want 30s", got[0].Timeout)
+	}
+}
+
+func TestLoadConfigRejectsDuplicatePaths(t *testing.T) {
+	path := writeConfig(t, `
+hooks:
+  - path: /hooks/deploy
+    command: echo one
+  - path: /hooks/deploy
+    command: echo two
+`)
+
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("LoadConfig: expected error for duplicate path, got nil")
+	}
+}
+
+func TestLoadConfigRejectsReservedPath(t *testing.T) {
+	path := writeConfig(t, `
+hooks:
+  - path: /health
+    command: echo hi
+`)
+
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("LoadConfig: expected error for reserved path /health, got nil")
+	}
+}
+
+func TestLoadConfigRejectsEmptyCommand(t *testing.T) {
+	path := writeConfig(t, `
+hooks:
+  - path: /hooks/deploy
+    command: "  "
+`)
+
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("LoadConfig: expected error for empty command, got nil")
+	}
+}
+
+func TestVerifySignatureAcceptsValidSignature(t *testing.T) {
+	secret := "s3cr3t"
+	body := []byte(`{"ref":"refs/heads/main"}`)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	header := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if err := verifySignature(secret, body, header); err != nil {
+		t.Errorf("verifySignature: unexpected error: %v", err)
+	}
+}
+
+func TestVerifySignatureRejectsBadSignature(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+
+	if err := verifySignature("s3cr3t", body, "sha256=deadbeef"); err == nil {
+		t.Error("verifySignature: expected error for bad signature, got nil")
+	}
+}
+
+func TestVerifySignatureRejectsMissingHeader(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+
+	if err := verifySignature("s3cr3t", body, ""); err == nil {
+		t.Error("verifySignature: expected error for missing header, got nil")
+	}
+}
+
+func TestVerifySignatureRejectsWrongSecret(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+
+	mac := hmac.New(sha256.New, []byte("right-secret"))
+	mac.Write(body)
+	header := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if err := verifySignature("wrong-secret", body, header); err == nil {
+		t.Error("verifySignature: expected error for wrong secret, got nil")
+	}
+}
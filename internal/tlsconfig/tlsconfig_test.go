@@ -0,0 +1,123 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTLSVersion(t *testing.T) {
+	cases := map[string]uint16{
+		"1.0": tls.VersionTLS10,
+		"1.1": tls.VersionTLS11,
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+	for in, want := range cases {
+		got, err := ParseTLSVersion(in)
+		if err != nil {
+			t.Fatalf("ParseTLSVersion(%q): unexpected error: %v", in, err)
+		}
+		if got != want {
+			t.Errorf("ParseTLSVersion(%q) = %d, want %d", in, got, want)
+		}
+	}
+
+	if _, err := ParseTLSVersion("2.0"); err == nil {
+		t.Error("ParseTLSVersion(\"2.0\"): expected error, got nil")
+	}
+}
+
+func TestLoadDefaultsWhenFileMissing(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "does-not-exist.yaml"))
+	if err != nil {
+		t.Fatalf("Load: unexpected error: %v", err)
+	}
+	if cfg.MinVersion != tls.VersionTLS12 {
+		t.Errorf("MinVersion = %d, want %d", cfg.MinVersion, tls.VersionTLS12)
+	}
+}
+
+func TestLoadParsesCipherSuitesAndVersions(t *testing.T) {
+	path := writeFile(t, `
+min_version: "1.2"
+max_version: "1.3"
+cipher_suites:
+  - TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256
+curve_preferences:
+  - X25519
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: unexpected error: %v", err)
+	}
+	if cfg.MinVersion != tls.VersionTLS12 || cfg.MaxVersion != tls.VersionTLS13 {
+		t.Errorf("got min=%d max=%d, want min=%d max=%d", cfg.MinVersion, cfg.MaxVersion, tls.VersionTLS12, tls.VersionTLS13)
+	}
+	if len(cfg.CipherSuites) != 1 || cfg.CipherSuites[0] != tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256 {
+		t.Errorf("CipherSuites = %v, want [%d]", cfg.CipherSuites, tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256)
+	}
+	if len(cfg.CurvePreferences) != 1 || cfg.CurvePreferences[0] != tls.X25519 {
+		t.Errorf("CurvePreferences = %v, want [%d]", cfg.CurvePreferences, tls.X25519)
+	}
+}
+
+func TestLoadRejectsUnknownCipherSuite(t *testing.T) {
+	path := writeFile(t, "cipher_suites:\n  - NOT_A_REAL_SUITE\n")
+
+	if _, err := Load(path); err == nil {
+		t.Error("Load: expected error for unknown cipher suite, got nil")
+	}
+}
+
+func TestLoadRequiresClientCAFileForVerify(t *testing.T) {
+	path := writeFile(t, "client_auth: verify\n")
+
+	if _, err := Load(path); err == nil {
+		t.Error("Load: expected error when client_auth=verify has no client_ca_file, got nil")
+	}
+}
+
+func TestLoadClientCAFilePopulatesClientCAs(t *testing.T) {
+	caPath := writeFile(t, testCACert)
+	path := writeFile(t, "client_auth: verify\nclient_ca_file: "+caPath+"\n")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: unexpected error: %v", err)
+	}
+	if cfg.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Errorf("ClientAuth = %v, want RequireAndVerifyClientCert", cfg.ClientAuth)
+	}
+	if cfg.ClientCAs == nil {
+		t.Fatal("ClientCAs is nil, want populated pool")
+	}
+	if len(cfg.ClientCAs.Subjects()) != 1 { //nolint:staticcheck // test-only inspection
+		t.Errorf("ClientCAs has %d subjects, want 1", len(cfg.ClientCAs.Subjects()))
+	}
+}
+
+func writeFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tlsconfig.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("writing test file: %v", err)
+	}
+	return path
+}
+
+// testCACert is a throwaway self-signed CA certificate used only to verify
+// that Load populates ClientCAs from client_ca_file.
+const testCACert = `-----BEGIN CERTIFICATE-----
+MIIBeTCCAR+gAwIBAgIUB0OcIHrCkNxSd70KdICw1qDgv4IwCgYIKoZIzj0EAwIw
+EjEQMA4GA1UECgwHVGVzdCBDQTAeFw0yNjA3MjUxMzA5NTRaFw0zNjA3MjIxMzA5
+NTRaMBIxEDAOBgNVBAoMB1Rlc3QgQ0EwWTATBgcqhkjOPQIBBggqhkjOPQMBBwNC
+AARaxJvcgC1FPud01LuczPiqYB3DlNqOE1MQpWluxPnwqCbk/HwY6Lh48mXi5i7G
+QxxEX7f3HamNy3xka52Tr1uUo1MwUTAdBgNVHQ4EFgQUuO3/tFzTXsr4oblWqUXn
+2UG+uDQwHwYDVR0jBBgwFoAUuO3/tFzTXsr4oblWqUXn2UG+uDQwDwYDVR0TAQH/
+BAUwAwEB/zAKBggqhkjOPQQDAgNIADBFAiEAl5eUdGikr1Zpzzq1f8HFjsH3eRyZ
+lZGSQ9QNkhvZ+vQCIGIE2yE66u6qqN/vOR1iHx/QBzzREZ+UrVYWNaxpvZFL
+-----END CERTIFICATE-----
+`
@@ -1,13 +1,29 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/crypto/acme/autocert"
+
+	"devops-mid-task/internal/hooks"
+	"devops-mid-task/internal/middleware"
+	"devops-mid-task/internal/tlsconfig"
 )
 
 type Response struct {
@@ -24,6 +40,11 @@ type HealthResponse struct {
 var startTime time.Time
 var apiVersion string
 
+// draining is set while the server is shutting down so /health can report
+// unhealthy and load balancers can deregister the instance before it stops
+// accepting connections.
+var draining atomic.Bool
+
 func init() {
 	startTime = time.Now()
 
@@ -46,8 +67,13 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
 func healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	uptime := time.Since(startTime).String()
+	status := "healthy"
+	if draining.Load() {
+		status = "draining"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
 	response := HealthResponse{
-		Status:  "healthy",
+		Status:  status,
 		Uptime:  uptime,
 		Version: apiVersion,
 	}
@@ -63,11 +89,170 @@ func aboutHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-func main() {
-	http.HandleFunc("/", homeHandler)
-	http.HandleFunc("/health", healthHandler)
-	http.HandleFunc("/about", aboutHandler)
+// newAutocertManager builds an autocert.Manager restricted to the hosts in
+// ACME_DOMAIN (comma-separated) and caching issued certificates under
+// ACME_CACHE_DIR.
+func newAutocertManager() *autocert.Manager {
+	domains := strings.Split(os.Getenv("ACME_DOMAIN"), ",")
+	for i := range domains {
+		domains[i] = strings.TrimSpace(domains[i])
+	}
+
+	cacheDir := os.Getenv("ACME_CACHE_DIR")
+	if cacheDir == "" {
+		cacheDir = "./certs/acme-cache"
+	}
+
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Cache:      autocert.DirCache(cacheDir),
+	}
+}
+
+// redirectToHTTPS sends every request to the HTTPS listener on httpsPort,
+// preserving host, path and query.
+func redirectToHTTPS(httpsPort string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if i := strings.IndexByte(host, ':'); i != -1 {
+			host = host[:i]
+		}
+		target := "https://" + host + ":" + httpsPort + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	}
+}
+
+// newACMEChallengeServer builds the plaintext HTTP server ACME needs to
+// complete the http-01 challenge, redirecting any non-challenge request to
+// the HTTPS listener.
+func newACMEChallengeServer(certManager *autocert.Manager, httpsPort string) *http.Server {
+	acmeHTTPPort := os.Getenv("ACME_HTTP_PORT")
+	if acmeHTTPPort == "" {
+		acmeHTTPPort = "80"
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/.well-known/acme-challenge/", certManager.HTTPHandler(nil))
+	mux.HandleFunc("/", redirectToHTTPS(httpsPort))
+
+	return &http.Server{
+		Addr:    ":" + acmeHTTPPort,
+		Handler: mux,
+	}
+}
+
+// newRedirectServer builds the plaintext HTTP listener that runs alongside a
+// statically-configured HTTPS server, 301-redirecting everything to it.
+func newRedirectServer(httpPort, httpsPort string) *http.Server {
+	return &http.Server{
+		Addr:    ":" + httpPort,
+		Handler: redirectToHTTPS(httpsPort),
+	}
+}
+
+// newAppMux registers the app's routes, wrapping them with security headers
+// when served over TLS.
+func newAppMux(secure bool) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", middleware.Instrument("/", homeHandler))
+	mux.HandleFunc("/health", middleware.Instrument("/health", healthHandler))
+	mux.HandleFunc("/about", middleware.Instrument("/about", aboutHandler))
+	mux.Handle("/metrics", promhttp.Handler())
+	registerHooks(mux)
+
+	if secure {
+		return middleware.SecurityHeaders(middleware.SecurityHeadersConfigFromEnv(), mux.ServeHTTP)
+	}
+	return mux
+}
+
+// registerHooks loads HOOKS_CONFIG, if set, and registers its webhook
+// endpoints on mux.
+func registerHooks(mux *http.ServeMux) {
+	path := os.Getenv("HOOKS_CONFIG")
+	if path == "" {
+		return
+	}
+
+	hookList, err := hooks.LoadConfig(path)
+	if err != nil {
+		log.Fatalf("loading hooks config: %v", err)
+	}
+
+	hooks.Register(mux, hookList)
+	fmt.Printf("Registered %d webhook(s) from %s\n", len(hookList), path)
+}
+
+// shutdownTimeout returns the grace period servers get to drain in-flight
+// requests before being forcibly closed, configurable via SHUTDOWN_TIMEOUT
+// (seconds).
+func shutdownTimeout() time.Duration {
+	if v := os.Getenv("SHUTDOWN_TIMEOUT"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 30 * time.Second
+}
+
+// preStopDelay is how long /health reports "draining" before the listeners
+// actually close, configurable via PRE_STOP_DELAY (seconds). This gives a
+// load balancer time to observe the unhealthy status and deregister the
+// instance before Shutdown cuts off new connections.
+func preStopDelay() time.Duration {
+	if v := os.Getenv("PRE_STOP_DELAY"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 5 * time.Second
+}
+
+// serve runs server in the background and logs a fatal error if it exits for
+// any reason other than a graceful Shutdown.
+func serve(server *http.Server, listen func() error) {
+	go func() {
+		if err := listen(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("server on %s: %v", server.Addr, err)
+		}
+	}()
+}
+
+// waitForShutdown blocks until SIGINT/SIGTERM is received, then drains the
+// given servers within the shutdown grace period.
+func waitForShutdown(servers ...*http.Server) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-sigCh
+
+	fmt.Printf("Received %s, draining connections...\n", sig)
+	draining.Store(true)
+
+	if delay := preStopDelay(); delay > 0 {
+		fmt.Printf("Waiting %s for load balancers to deregister...\n", delay)
+		time.Sleep(delay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server *http.Server) {
+			defer wg.Done()
+			if err := server.Shutdown(ctx); err != nil {
+				log.Printf("error shutting down server on %s: %v", server.Addr, err)
+			}
+		}(server)
+	}
+	wg.Wait()
 
+	fmt.Println("Shutdown complete")
+}
+
+func main() {
 	// Get certificate and key paths from environment or use defaults
 	certFile := os.Getenv("TLS_CERT_FILE")
 	if certFile == "" {
@@ -79,6 +264,8 @@ func main() {
 		keyFile = "certs/server.key"
 	}
 
+	acmeDomains := os.Getenv("ACME_DOMAIN")
+
 	// Check if certificates exist
 	if _, err := os.Stat(certFile); err == nil {
 		// Certificates found, start HTTPS server
@@ -87,32 +274,79 @@ func main() {
 			httpsPort = "8443"
 		}
 
-		// Configure TLS
-		tlsConfig := &tls.Config{
-			MinVersion:               tls.VersionTLS12,
-			CurvePreferences:         []tls.CurveID{tls.CurveP521, tls.CurveP384, tls.CurveP256},
-			PreferServerCipherSuites: true,
-			CipherSuites: []uint16{
-				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
-				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
-				tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
-				tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
-				tls.TLS_RSA_WITH_AES_256_CBC_SHA,
-			},
+		// Configure TLS, optionally tuned via TLS_CONFIG_FILE.
+		cfg, err := tlsconfig.Load(os.Getenv("TLS_CONFIG_FILE"))
+		if err != nil {
+			log.Fatalf("loading TLS config: %v", err)
 		}
 
 		server := &http.Server{
 			Addr:         ":" + httpsPort,
-			TLSConfig:    tlsConfig,
+			Handler:      newAppMux(true),
+			TLSConfig:    cfg,
 			ReadTimeout:  15 * time.Second,
 			WriteTimeout: 15 * time.Second,
 			IdleTimeout:  60 * time.Second,
 		}
 
+		httpPort := os.Getenv("HTTP_PORT")
+		if httpPort == "" {
+			httpPort = "8080"
+		}
+		redirectServer := newRedirectServer(httpPort, httpsPort)
+
 		fmt.Printf("Server starting with HTTPS on port %s...\n", httpsPort)
 		fmt.Printf("API Version: %s\n", apiVersion)
 		fmt.Printf("Using TLS certificate: %s\n", certFile)
-		log.Fatal(server.ListenAndServeTLS(certFile, keyFile))
+		fmt.Printf("Redirecting HTTP on port %s to HTTPS...\n", httpPort)
+		serve(server, func() error { return server.ListenAndServeTLS(certFile, keyFile) })
+		serve(redirectServer, redirectServer.ListenAndServe)
+		waitForShutdown(server, redirectServer)
+	} else if acmeDomains != "" {
+		// No static certificate, but ACME is configured: provision certs
+		// automatically via Let's Encrypt.
+		httpsPort := os.Getenv("HTTPS_PORT")
+		if httpsPort == "" {
+			httpsPort = "8443"
+		}
+
+		certManager := newAutocertManager()
+
+		cfg, err := tlsconfig.Load(os.Getenv("TLS_CONFIG_FILE"))
+		if err != nil {
+			log.Fatalf("loading TLS config: %v", err)
+		}
+		cfg.GetCertificate = certManager.GetCertificate
+
+		server := &http.Server{
+			Addr:         ":" + httpsPort,
+			Handler:      newAppMux(true),
+			TLSConfig:    cfg,
+			ReadTimeout:  15 * time.Second,
+			WriteTimeout: 15 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		}
+
+		challengeServer := newACMEChallengeServer(certManager, httpsPort)
+
+		// autocert.Manager.Listener() always binds :443 regardless of
+		// server.Addr, so build our own listener on httpsPort instead and
+		// wrap it with cfg (not the manager's own minimal TLS config) so
+		// TLS_CONFIG_FILE's version/cipher/client-auth settings actually
+		// apply to ACME-served connections too.
+		ln, err := net.Listen("tcp", server.Addr)
+		if err != nil {
+			log.Fatalf("listening on %s: %v", server.Addr, err)
+		}
+		tlsListener := tls.NewListener(ln, cfg)
+
+		fmt.Printf("Server starting with HTTPS on port %s...\n", httpsPort)
+		fmt.Printf("API Version: %s\n", apiVersion)
+		fmt.Printf("Using ACME-provisioned certificate for: %s\n", acmeDomains)
+		fmt.Printf("ACME HTTP-01 challenge server listening on port %s...\n", strings.TrimPrefix(challengeServer.Addr, ":"))
+		serve(server, func() error { return server.Serve(tlsListener) })
+		serve(challengeServer, challengeServer.ListenAndServe)
+		waitForShutdown(server, challengeServer)
 	} else {
 		// No certificates found, start HTTP server
 		httpPort := os.Getenv("HTTP_PORT")
@@ -120,9 +354,12 @@ func main() {
 			httpPort = "8080"
 		}
 
+		server := &http.Server{Addr: ":" + httpPort, Handler: newAppMux(false)}
+
 		fmt.Printf("Server starting with HTTP on port %s...\n", httpPort)
 		fmt.Printf("API Version: %s\n", apiVersion)
 		fmt.Println("Warning: Running without TLS encryption")
-		log.Fatal(http.ListenAndServe(":"+httpPort, nil))
+		serve(server, server.ListenAndServe)
+		waitForShutdown(server)
 	}
 }